@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds the application configuration
@@ -10,15 +12,84 @@ type Config struct {
 	NATSURL      string
 	JWTPublicKey string
 	LogLevel     string
+
+	// Broker selects the PubSub backend: "nats" (default), "kafka", or "redis".
+	Broker       string
+	KafkaBrokers string
+	RedisURL     string
+
+	// PingTimeout is the keepalive interval (and per-ping deadline) for the
+	// streaming ingest WebSocket endpoint.
+	PingTimeout time.Duration
+
+	// StreamInFlightWindow caps the number of events a single streaming
+	// connection may have submitted for publish but not yet acked.
+	StreamInFlightWindow int
+
+	// Batch ingest tuning
+	IngestWorkers      int
+	BatchMaxEvents     int
+	QueueHighWaterMark int
+	WALPath            string
+	RateLimitPerSecond float64
+	RateLimitBurst     float64
+
+	// JWT validation. JWTPublicKeyFile, JWTJWKSURL, and JWTIssuer are tried in
+	// that order to build the key source; JWTTenantIssuers overrides the
+	// issuer/audience allowlist per tenant.
+	JWTPublicKeyFile string
+	JWTJWKSURL       string
+	JWTIssuer        string
+	JWTAudience      string
+	JWTClockSkew     time.Duration
+	JWTTenantIssuers string
+
+	// Dedup selects the idempotency store backend: "memory" (default) or
+	// "redis". IdempotencyTTL bounds how long a submitted Idempotency-Key (or
+	// derived event ID) is remembered before a retry is treated as new.
+	DedupBackend   string
+	DedupCapacity  int
+	IdempotencyTTL time.Duration
+
+	// RawStoreDir is the base directory transformers persist raw ingest
+	// payloads under before handing back a content-addressed RawRef. A
+	// local directory in dev; mount an object-store-backed volume in
+	// production.
+	RawStoreDir string
 }
 
 // Load reads configuration from environment variables with defaults
 func Load() *Config {
 	return &Config{
-		Port:         getEnv("PORT", "8080"),
-		NATSURL:      getEnv("NATS_URL", "nats://nats:4222"),
-		JWTPublicKey: getEnv("JWT_PUBLIC_KEY", ""),
-		LogLevel:     getEnv("LOG_LEVEL", "info"),
+		Port:                 getEnv("PORT", "8080"),
+		NATSURL:              getEnv("NATS_URL", "nats://nats:4222"),
+		JWTPublicKey:         getEnv("JWT_PUBLIC_KEY", ""),
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		Broker:               getEnv("BROKER", "nats"),
+		KafkaBrokers:         getEnv("KAFKA_BROKERS", "localhost:9092"),
+		RedisURL:             getEnv("REDIS_URL", "redis://localhost:6379"),
+		PingTimeout:          getEnvDuration("PING_TIMEOUT", 30*time.Second),
+		StreamInFlightWindow: getEnvInt("STREAM_IN_FLIGHT_WINDOW", 128),
+
+		IngestWorkers:      getEnvInt("INGEST_WORKERS", 4),
+		BatchMaxEvents:     getEnvInt("BATCH_MAX_EVENTS", 1000),
+		QueueHighWaterMark: getEnvInt("QUEUE_HIGH_WATER_MARK", 5000),
+		WALPath:            getEnv("WAL_PATH", "data/ingest.wal"),
+		RateLimitPerSecond: getEnvFloat("RATE_LIMIT_PER_SECOND", 500),
+		RateLimitBurst:     getEnvFloat("RATE_LIMIT_BURST", 1000),
+
+		JWTPublicKeyFile: getEnv("JWT_PUBLIC_KEY_FILE", ""),
+		JWTJWKSURL:       getEnv("JWT_JWKS_URL", ""),
+		JWTIssuer:        getEnv("JWT_ISSUER", ""),
+		JWTAudience:      getEnv("JWT_AUDIENCE", ""),
+		JWTClockSkew:     getEnvDuration("JWT_CLOCK_SKEW", 60*time.Second),
+		JWTTenantIssuers: getEnv("JWT_TENANT_ISSUERS", ""),
+
+		DedupBackend:   getEnv("DEDUP_BACKEND", "memory"),
+		DedupCapacity:  getEnvInt("DEDUP_CAPACITY", 100000),
+		IdempotencyTTL: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		RawStoreDir: getEnv("RAW_STORE_DIR", "data/raw"),
 	}
 }
 
@@ -28,3 +99,30 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
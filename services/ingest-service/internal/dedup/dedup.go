@@ -0,0 +1,16 @@
+// Package dedup tracks idempotency keys so a retried ingest request is
+// answered with the event_id from the original submission instead of being
+// published a second time.
+package dedup
+
+import "time"
+
+// Store records idempotency keys against the event_id they were first seen
+// with. Implementations must make CheckAndSet atomic: concurrent calls for
+// the same key must not both report the key as new.
+type Store interface {
+	// CheckAndSet records key -> eventID if key has not been seen within ttl.
+	// If key was already recorded, it returns the original eventID and
+	// duplicate=true without overwriting the stored value.
+	CheckAndSet(key, eventID string, ttl time.Duration) (existingEventID string, duplicate bool, err error)
+}
@@ -0,0 +1,17 @@
+package dedup
+
+import "fmt"
+
+// NewFromConfig selects and constructs a Store backend by name.
+// backend is one of "memory" or "redis"; capacity and redisURL are only
+// consulted by the backend they apply to.
+func NewFromConfig(backend string, capacity int, redisURL string) (Store, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryStore(capacity), nil
+	case "redis":
+		return NewRedisStore(redisURL)
+	default:
+		return nil, fmt.Errorf("unknown dedup backend %q: must be memory or redis", backend)
+	}
+}
@@ -0,0 +1,64 @@
+package dedup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory, size-bounded LRU idempotency store suitable
+// for local development and single-instance deployments. Entries also expire
+// on their own TTL regardless of LRU pressure.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key       string
+	eventID   string
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an in-memory dedup store holding at most capacity entries.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) CheckAndSet(key, eventID string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		if now.Before(entry.expiresAt) {
+			s.ll.MoveToFront(el)
+			return entry.eventID, true, nil
+		}
+		// Expired: evict and fall through to treat this as a new key.
+		s.ll.Remove(el)
+		delete(s.items, key)
+	}
+
+	el := s.ll.PushFront(&memoryEntry{key: key, eventID: eventID, expiresAt: now.Add(ttl)})
+	s.items[key] = el
+
+	for s.ll.Len() > s.capacity {
+		oldest := s.ll.Back()
+		if oldest == nil {
+			break
+		}
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryEntry).key)
+	}
+
+	return eventID, false, nil
+}
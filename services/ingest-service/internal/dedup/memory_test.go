@@ -0,0 +1,91 @@
+package dedup
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreDetectsDuplicate(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	eventID, duplicate, err := store.CheckAndSet("acme-corp:key1", "evt-1", time.Minute)
+	if err != nil {
+		t.Fatalf("CheckAndSet failed: %v", err)
+	}
+	if duplicate {
+		t.Error("Expected first call to report no duplicate")
+	}
+	if eventID != "evt-1" {
+		t.Errorf("Expected evt-1, got %s", eventID)
+	}
+
+	existing, duplicate, err := store.CheckAndSet("acme-corp:key1", "evt-2", time.Minute)
+	if err != nil {
+		t.Fatalf("CheckAndSet failed: %v", err)
+	}
+	if !duplicate {
+		t.Error("Expected second call with same key to report duplicate")
+	}
+	if existing != "evt-1" {
+		t.Errorf("Expected original event_id evt-1, got %s", existing)
+	}
+}
+
+func TestMemoryStoreExpiresEntries(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	if _, _, err := store.CheckAndSet("acme-corp:key1", "evt-1", time.Millisecond); err != nil {
+		t.Fatalf("CheckAndSet failed: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, duplicate, err := store.CheckAndSet("acme-corp:key1", "evt-2", time.Minute)
+	if err != nil {
+		t.Fatalf("CheckAndSet failed: %v", err)
+	}
+	if duplicate {
+		t.Error("Expected expired entry to be treated as new")
+	}
+}
+
+func TestMemoryStoreEvictsOverCapacity(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	store.CheckAndSet("k1", "evt-1", time.Minute)
+	store.CheckAndSet("k2", "evt-2", time.Minute)
+	store.CheckAndSet("k3", "evt-3", time.Minute)
+
+	_, duplicate, _ := store.CheckAndSet("k1", "evt-1-retry", time.Minute)
+	if duplicate {
+		t.Error("Expected oldest key to have been evicted")
+	}
+}
+
+func TestMemoryStoreConcurrentDuplicates(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	var wg sync.WaitGroup
+	duplicates := make([]bool, 20)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, duplicate, _ := store.CheckAndSet("acme-corp:shared", "evt-shared", time.Minute)
+			duplicates[i] = duplicate
+		}(i)
+	}
+	wg.Wait()
+
+	var nonDuplicates int
+	for _, d := range duplicates {
+		if !d {
+			nonDuplicates++
+		}
+	}
+	if nonDuplicates != 1 {
+		t.Errorf("Expected exactly 1 non-duplicate among concurrent submissions, got %d", nonDuplicates)
+	}
+}
@@ -0,0 +1,47 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a production idempotency store shared across all instances
+// of the service, backed by Redis SET NX EX for atomic check-and-set.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a dedup store against the given Redis connection URL.
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) CheckAndSet(key, eventID string, ttl time.Duration) (string, bool, error) {
+	ctx := context.Background()
+
+	ok, err := s.client.SetNX(ctx, key, eventID, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		return eventID, false, nil
+	}
+
+	existing, err := s.client.Get(ctx, key).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return existing, true, nil
+}
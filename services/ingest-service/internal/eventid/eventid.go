@@ -0,0 +1,37 @@
+// Package eventid derives deterministic, content-addressable event IDs so
+// that republishing the same client payload for the same tenant produces the
+// same event_id instead of a new one each time.
+package eventid
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// Compute derives a UUIDv8 event ID from sha256(tenantID + canonical JSON of
+// event). Callers must call this before setting any server-assigned fields
+// (EventID, Timestamp, Source.Host) so the hash only covers client-supplied data.
+func Compute(tenantID string, event *models.Event) (string, error) {
+	canonical, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(tenantID+":"), canonical...))
+	return encodeUUIDv8(sum), nil
+}
+
+// encodeUUIDv8 packs the first 16 bytes of a hash into RFC 9562 UUIDv8 layout:
+// version nibble set to 8, variant bits set to RFC 4122, everything else is
+// hash entropy.
+func encodeUUIDv8(sum [32]byte) string {
+	var b [16]byte
+	copy(b[:], sum[:16])
+	b[6] = (b[6] & 0x0f) | 0x80
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,49 @@
+package eventid
+
+import (
+	"testing"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+func TestComputeIsDeterministic(t *testing.T) {
+	event := &models.Event{Category: "auth", Severity: 5}
+
+	id1, err := Compute("acme-corp", event)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	id2, err := Compute("acme-corp", event)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	if id1 != id2 {
+		t.Errorf("Expected same input to produce the same event ID, got %s and %s", id1, id2)
+	}
+}
+
+func TestComputeVariesByTenant(t *testing.T) {
+	event := &models.Event{Category: "auth", Severity: 5}
+
+	id1, _ := Compute("acme-corp", event)
+	id2, _ := Compute("initech", event)
+
+	if id1 == id2 {
+		t.Error("Expected different tenants to produce different event IDs for identical payloads")
+	}
+}
+
+func TestComputeProducesUUIDv8Layout(t *testing.T) {
+	id, err := Compute("acme-corp", &models.Event{Category: "auth"})
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	if len(id) != 36 {
+		t.Fatalf("Expected a 36-character UUID string, got %q", id)
+	}
+	if id[14] != '8' {
+		t.Errorf("Expected version nibble 8, got %q in %s", id[14], id)
+	}
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/siem-platform/ingest-service/internal/dedup"
+	"github.com/siem-platform/ingest-service/internal/models"
+	"github.com/siem-platform/ingest-service/internal/publisher"
+	"github.com/siem-platform/ingest-service/internal/ratelimit"
+	"github.com/siem-platform/ingest-service/internal/wal"
+)
+
+func newTestBatchHandler(t *testing.T) (*BatchHandler, *publisher.MockPublisher) {
+	t.Helper()
+
+	w, err := wal.Open(filepath.Join(t.TempDir(), "ingest.wal"))
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	mockPub := publisher.NewMockPublisher()
+	limiter := ratelimit.New(100, 1000)
+
+	return NewBatchHandler(mockPub, w, limiter, dedup.NewMemoryStore(1000), time.Hour, 2, 1000, 5000), mockPub
+}
+
+func TestIngestBatchJSONArray(t *testing.T) {
+	handler, mockPub := newTestBatchHandler(t)
+
+	body := []byte(`[{"category":"auth","severity":1},{"category":"network","severity":2}]`)
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rr.Code)
+	}
+
+	var results []BatchResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Status != "accepted" {
+			t.Errorf("Expected accepted, got %+v", r)
+		}
+	}
+
+	published := mockPub.GetPublished("raw.events.acme-corp")
+	if len(published) != 2 {
+		t.Errorf("Expected 2 published events, got %d", len(published))
+	}
+}
+
+func TestIngestBatchNDJSON(t *testing.T) {
+	handler, _ := newTestBatchHandler(t)
+
+	body := []byte("{\"category\":\"auth\"}\n{\"category\":\"network\"}\n")
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rr.Code)
+	}
+}
+
+func TestIngestBatchRejectsOversizedBatch(t *testing.T) {
+	w, err := wal.Open(filepath.Join(t.TempDir(), "ingest.wal"))
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	defer w.Close()
+
+	handler := NewBatchHandler(publisher.NewMockPublisher(), w, ratelimit.New(100, 1000), dedup.NewMemoryStore(1000), time.Hour, 1, 1, 100)
+
+	body := []byte(`[{"category":"auth"},{"category":"network"}]`)
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestIngestBatchDeduplicatesRepeatedEvents(t *testing.T) {
+	handler, mockPub := newTestBatchHandler(t)
+
+	body := []byte(`[{"category":"auth","severity":1},{"category":"auth","severity":1}]`)
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rr.Code)
+	}
+
+	var results []BatchResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if results[0].Status != "accepted" {
+		t.Errorf("Expected first identical event to be accepted, got %+v", results[0])
+	}
+	if results[1].Status != "duplicate" || results[1].EventID != results[0].EventID {
+		t.Errorf("Expected second identical event to be reported as a duplicate of %s, got %+v", results[0].EventID, results[1])
+	}
+
+	published := mockPub.GetPublished("raw.events.acme-corp")
+	if len(published) != 1 {
+		t.Errorf("Expected exactly 1 published event, got %d", len(published))
+	}
+}
+
+// TestIngestBatchHonorsIdempotencyKeyOnRetry confirms a full-batch retry
+// sent with the same Idempotency-Key is recognized as a retry
+// position-by-position, even though the two batches carry different event
+// content (e.g. a severity field that changed between attempts) and so
+// would not collide on content hash alone.
+func TestIngestBatchHonorsIdempotencyKeyOnRetry(t *testing.T) {
+	handler, mockPub := newTestBatchHandler(t)
+
+	body := []byte(`[{"category":"auth","severity":1},{"category":"network","severity":2}]`)
+	retryBody := []byte(`[{"category":"auth","severity":9},{"category":"network","severity":9}]`)
+
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req.Header.Set("Idempotency-Key", "batch-123")
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	var firstResults []BatchResult
+	if err := json.NewDecoder(rr.Body).Decode(&firstResults); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, r := range firstResults {
+		if r.Status != "accepted" {
+			t.Fatalf("Expected first submission to be accepted, got %+v", r)
+		}
+	}
+
+	retryReq := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(retryBody))
+	retryReq.Header.Set("Idempotency-Key", "batch-123")
+	retryReq = retryReq.WithContext(context.WithValue(retryReq.Context(), "tenant_id", "acme-corp"))
+
+	retryRR := httptest.NewRecorder()
+	handler.IngestBatch(retryRR, retryReq)
+
+	var retryResults []BatchResult
+	if err := json.NewDecoder(retryRR.Body).Decode(&retryResults); err != nil {
+		t.Fatalf("Failed to decode retry response: %v", err)
+	}
+	for i, r := range retryResults {
+		if r.Status != "duplicate" || r.EventID != firstResults[i].EventID {
+			t.Errorf("Expected retried event %d to be reported as a duplicate of %s, got %+v", i, firstResults[i].EventID, r)
+		}
+	}
+
+	published := mockPub.GetPublished("raw.events.acme-corp")
+	if len(published) != 2 {
+		t.Errorf("Expected exactly 2 published events across both submissions, got %d", len(published))
+	}
+}
+
+// TestNewBatchHandlerReplayCountsTowardPublishedMetric confirms events
+// republished from the WAL on startup count toward ingest_published_total
+// the same as events published through the normal worker path, since they
+// were genuinely published to the broker either way.
+func TestNewBatchHandlerReplayCountsTowardPublishedMetric(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "ingest.wal")
+	w, err := wal.Open(walPath)
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	if err := w.Append(wal.Entry{Topic: "raw.events.acme-corp", Event: &models.Event{TenantID: "acme-corp", EventID: "evt-replay-1"}}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := w.Append(wal.Entry{Topic: "raw.events.acme-corp", Event: &models.Event{TenantID: "acme-corp", EventID: "evt-replay-2"}}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close WAL failed: %v", err)
+	}
+
+	w, err = wal.Open(walPath)
+	if err != nil {
+		t.Fatalf("Re-open WAL failed: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	mockPub := publisher.NewMockPublisher()
+	handler := NewBatchHandler(mockPub, w, ratelimit.New(100, 1000), dedup.NewMemoryStore(1000), time.Hour, 2, 1000, 5000)
+
+	if got := handler.published.Load(); got != 2 {
+		t.Errorf("Expected 2 events republished from the WAL to count as published, got %d", got)
+	}
+	if got := len(mockPub.GetPublished("raw.events.acme-corp")); got != 2 {
+		t.Errorf("Expected 2 events republished to the broker, got %d", got)
+	}
+}
+
+func TestIngestBatchRateLimited(t *testing.T) {
+	w, err := wal.Open(filepath.Join(t.TempDir(), "ingest.wal"))
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	defer w.Close()
+
+	handler := NewBatchHandler(publisher.NewMockPublisher(), w, ratelimit.New(0, 0), dedup.NewMemoryStore(1000), time.Hour, 1, 1000, 100)
+
+	body := []byte(`[{"category":"auth"}]`)
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
+
+// TestIngestBatchHighWaterMarkReservesCapacityAtomically guards against the
+// high-water-mark check being a check-then-act race: two concurrent 2-event
+// batches against a highWaterMark (and jobs channel capacity) of 2 request 4
+// slots combined. If the check only ever reads a stale queueDepth snapshot
+// instead of reserving capacity atomically, both requests can see the queue
+// as empty, both pass, and together try to push 4 jobs into a 2-slot
+// channel — blocking the losing request's goroutine on the channel send
+// instead of returning 429.
+func TestIngestBatchHighWaterMarkReservesCapacityAtomically(t *testing.T) {
+	w, err := wal.Open(filepath.Join(t.TempDir(), "ingest.wal"))
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	defer w.Close()
+
+	release := make(chan struct{})
+	pub := &blockingPublisher{started: make(chan struct{}, 4), release: release}
+
+	handler := NewBatchHandler(pub, w, ratelimit.New(100, 1000), dedup.NewMemoryStore(1000), time.Hour, 1, 1000, 2)
+
+	newReq := func(severityA, severityB int) *http.Request {
+		body := []byte(fmt.Sprintf(`[{"category":"auth","severity":%d},{"category":"auth","severity":%d}]`, severityA, severityB))
+		req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+	}
+
+	var start sync.WaitGroup
+	start.Add(1)
+	codes := make(chan int, 2)
+	for _, pair := range [][2]int{{1, 2}, {3, 4}} {
+		pair := pair
+		go func() {
+			start.Wait()
+			rr := httptest.NewRecorder()
+			handler.IngestBatch(rr, newReq(pair[0], pair[1]))
+			codes <- rr.Code
+		}()
+	}
+	start.Done()
+
+	waitForCode := func(timeout time.Duration, what string) int {
+		t.Helper()
+		select {
+		case code := <-codes:
+			return code
+		case <-time.After(timeout):
+			t.Fatalf("Timed out waiting for %s to return", what)
+			return 0
+		}
+	}
+
+	// The batch that loses the reservation race must return immediately;
+	// the batch that wins is still blocked in wg.Wait() on the worker, which
+	// can't make progress until Publish is unblocked below.
+	rejectedCode := waitForCode(300*time.Millisecond, "the batch rejected for lack of queue capacity")
+	if rejectedCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected the losing batch to be rejected with %d, got %d", http.StatusTooManyRequests, rejectedCode)
+	}
+
+	close(release)
+
+	acceptedCode := waitForCode(time.Second, "the batch accepted once queue capacity was reserved")
+	if acceptedCode != http.StatusMultiStatus {
+		t.Errorf("Expected the winning batch to be accepted with %d, got %d", http.StatusMultiStatus, acceptedCode)
+	}
+}
+
+func TestIngestBatchRateLimitScalesWithEventCount(t *testing.T) {
+	w, err := wal.Open(filepath.Join(t.TempDir(), "ingest.wal"))
+	if err != nil {
+		t.Fatalf("Open WAL failed: %v", err)
+	}
+	defer w.Close()
+
+	// Capacity of 3 tokens should admit a 3-event batch but reject a
+	// 4-event batch, proving the limiter charges per event rather than
+	// a single token per request regardless of batch size.
+	handler := NewBatchHandler(publisher.NewMockPublisher(), w, ratelimit.New(3, 0), dedup.NewMemoryStore(1000), time.Hour, 1, 1000, 100)
+
+	body := []byte(`[{"category":"auth"},{"category":"auth"},{"category":"auth"},{"category":"auth"}]`)
+	req := httptest.NewRequest("POST", "/v1/ingest/batch", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+
+	rr := httptest.NewRecorder()
+	handler.IngestBatch(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a 4-event batch against a 3-token bucket to be rate limited, got status %d", rr.Code)
+	}
+}
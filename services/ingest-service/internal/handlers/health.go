@@ -19,11 +19,3 @@ func ReadyHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
 }
-
-// MetricsHandler returns Prometheus metrics (stub for now)
-func MetricsHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(http.StatusOK)
-	// TODO: Implement Prometheus metrics
-	w.Write([]byte("# No metrics yet\n"))
-}
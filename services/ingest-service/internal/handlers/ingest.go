@@ -3,24 +3,30 @@ package handlers
 import (
 	"encoding/json"
 	"io"
+	"mime"
 	"net/http"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
-	"github.com/siem-platform/ingest-service/internal/models"
+	"github.com/siem-platform/ingest-service/internal/dedup"
+	"github.com/siem-platform/ingest-service/internal/eventid"
 	"github.com/siem-platform/ingest-service/internal/publisher"
+	"github.com/siem-platform/ingest-service/internal/transformers"
 )
 
 // IngestHandler handles event ingestion
 type IngestHandler struct {
-	publisher publisher.Publisher
+	publisher      publisher.Publisher
+	dedupStore     dedup.Store
+	idempotencyTTL time.Duration
 }
 
 // NewIngestHandler creates a new ingest handler
-func NewIngestHandler(pub publisher.Publisher) *IngestHandler {
+func NewIngestHandler(pub publisher.Publisher, dedupStore dedup.Store, idempotencyTTL time.Duration) *IngestHandler {
 	return &IngestHandler{
-		publisher: pub,
+		publisher:      pub,
+		dedupStore:     dedupStore,
+		idempotencyTTL: idempotencyTTL,
 	}
 }
 
@@ -48,11 +54,21 @@ func (h *IngestHandler) IngestEvents(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	// Parse event
-	var event models.Event
-	if err := json.Unmarshal(body, &event); err != nil {
-		log.Error().Err(err).Msg("Failed to parse event")
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	// Select the transformer for this payload: ?format= takes precedence over Content-Type
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	}
+
+	transformer, ok := transformers.Get(format)
+	if !ok {
+		transformer, _ = transformers.Get("application/json")
+	}
+
+	event, err := transformer.Transform(body, format)
+	if err != nil {
+		log.Error().Err(err).Str("format", format).Msg("Failed to transform event")
+		http.Error(w, "Invalid payload: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -62,15 +78,47 @@ func (h *IngestHandler) IngestEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Derive a content-addressable event ID before any server-assigned field
+	// is set, so republishing the same payload is idempotent on its own.
+	eventID, err := eventid.Compute(tenantID, event)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to compute event ID")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// An explicit Idempotency-Key takes precedence over the content hash so a
+	// client can force distinct submissions of identical content to collide
+	// (or vice versa) per draft-ietf-httpapi-idempotency-key.
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = eventID
+	}
+	dedupKey := tenantID + ":" + idempotencyKey
+
+	existingEventID, duplicate, err := h.dedupStore.CheckAndSet(dedupKey, eventID, h.idempotencyTTL)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check idempotency store")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if duplicate {
+		log.Info().Str("tenant_id", tenantID).Str("event_id", existingEventID).Msg("Duplicate event, returning original result")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"event_id": existingEventID, "status": "duplicate"})
+		return
+	}
+
 	// Enrich event with metadata
 	event.TenantID = tenantID
-	event.EventID = uuid.New().String()
+	event.EventID = eventID
 	event.Timestamp = time.Now().UTC().Format(time.RFC3339)
 	event.Source.Host = r.RemoteAddr
 
 	// Publish to NATS
 	topic := "raw.events." + tenantID
-	if err := h.publisher.Publish(topic, &event); err != nil {
+	if err := h.publisher.Publish(topic, event); err != nil {
 		log.Error().
 			Err(err).
 			Str("tenant_id", tenantID).
@@ -7,13 +7,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/siem-platform/ingest-service/internal/dedup"
 	"github.com/siem-platform/ingest-service/internal/publisher"
 )
 
 func TestIngestEvents(t *testing.T) {
 	mockPub := publisher.NewMockPublisher()
-	handler := NewIngestHandler(mockPub)
+	handler := NewIngestHandler(mockPub, dedup.NewMemoryStore(1000), time.Hour)
 
 	tests := []struct {
 		name           string
@@ -88,3 +90,41 @@ func TestIngestEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestIngestEventsDeduplicatesRetries(t *testing.T) {
+	mockPub := publisher.NewMockPublisher()
+	handler := NewIngestHandler(mockPub, dedup.NewMemoryStore(1000), time.Hour)
+
+	body := []byte(`{"category":"auth","severity":5}`)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/v1/ingest/events", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(context.WithValue(req.Context(), "tenant_id", "acme-corp"))
+	}
+
+	rr1 := httptest.NewRecorder()
+	handler.IngestEvents(rr1, newRequest())
+	if rr1.Code != http.StatusAccepted {
+		t.Fatalf("Expected first submission to be accepted, got %d", rr1.Code)
+	}
+	var first map[string]string
+	json.NewDecoder(rr1.Body).Decode(&first)
+
+	rr2 := httptest.NewRecorder()
+	handler.IngestEvents(rr2, newRequest())
+	if rr2.Code != http.StatusOK {
+		t.Fatalf("Expected duplicate submission to return 200 OK, got %d", rr2.Code)
+	}
+	var second map[string]string
+	json.NewDecoder(rr2.Body).Decode(&second)
+
+	if second["event_id"] != first["event_id"] {
+		t.Errorf("Expected duplicate to return original event_id %s, got %s", first["event_id"], second["event_id"])
+	}
+
+	published := mockPub.GetPublished("raw.events.acme-corp")
+	if len(published) != 1 {
+		t.Errorf("Expected exactly 1 published event, got %d", len(published))
+	}
+}
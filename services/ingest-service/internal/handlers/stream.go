@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+	"github.com/siem-platform/ingest-service/internal/publisher"
+)
+
+// streamAck is sent back to the collector agent for every event it pushes.
+type streamAck struct {
+	EventID string `json:"event_id"`
+	Status  string `json:"status"`
+	Seq     uint64 `json:"seq"`
+}
+
+// StreamHandler handles the long-lived WebSocket ingestion endpoint used by
+// high-throughput collector agents that would otherwise pay per-request
+// overhead on /v1/ingest/events.
+type StreamHandler struct {
+	publisher      publisher.PubSub
+	pingTimeout    time.Duration
+	inFlightWindow int
+
+	mu     sync.Mutex
+	conns  map[*streamConn]struct{}
+	connWG sync.WaitGroup
+
+	received           atomic.Int64
+	published          atomic.Int64
+	dropped            atomic.Int64
+	backpressureEvents atomic.Int64
+}
+
+type streamConn struct {
+	conn *websocket.Conn
+}
+
+// NewStreamHandler creates a new streaming ingest handler. inFlightWindow
+// bounds how many events a single connection may have submitted for publish
+// but not yet acked; once that many are outstanding, reading further
+// messages off the socket blocks until an in-flight publish completes.
+func NewStreamHandler(pub publisher.PubSub, pingTimeout time.Duration, inFlightWindow int) *StreamHandler {
+	return &StreamHandler{
+		publisher:      pub,
+		pingTimeout:    pingTimeout,
+		inFlightWindow: inFlightWindow,
+		conns:          make(map[*streamConn]struct{}),
+	}
+}
+
+// HandleStream handles GET /v1/ingest/stream. Auth has already run via the
+// same middleware chain mounted for /v1/ingest/events, so tenant_id is
+// available in the request context before the handshake completes.
+//
+// A single reader goroutine pulls JSON messages off the socket, one event per
+// WebSocket message; a message holding several newline-delimited events is
+// not split and fails to decode, which ends the read loop and the
+// connection. Each decoded event is published and acked on its own goroutine so
+// a slow publish doesn't stall the read loop. The in-flight semaphore bounds
+// how many of those publish goroutines may be outstanding at once, and a
+// dedicated writer goroutine serializes acks back onto the connection.
+func (h *StreamHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := r.Context().Value("tenant_id").(string)
+	if !ok || tenantID == "" {
+		log.Error().Msg("Tenant ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to upgrade to WebSocket")
+		return
+	}
+
+	// websocket.Accept hijacks the connection, so net/http never clears the
+	// http.Server's WriteTimeout on it the way it does for a normal request;
+	// nhooyr.io/websocket manages its own per-call context timeouts and never
+	// touches the deadline itself. Left alone, every write on this connection
+	// (acks, pings) starts failing with i/o timeout once WriteTimeout elapses,
+	// well before a long-lived stream is done with the connection.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to clear write deadline on stream connection")
+	}
+
+	sc := &streamConn{conn: conn}
+	h.trackConn(sc)
+	// Tracked until the read loop has broken, every in-flight processMessage
+	// goroutine has finished, and the ack writer has drained, so Shutdown can
+	// wait on connWG instead of returning as soon as the socket is closed.
+	h.connWG.Add(1)
+	defer h.connWG.Done()
+	defer h.untrackConn(sc)
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	ctx := r.Context()
+	go h.keepalive(ctx, conn)
+
+	acks := make(chan streamAck, h.inFlightWindow)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		for ack := range acks {
+			if err := wsjson.Write(ctx, conn, ack); err != nil {
+				log.Warn().Err(err).Msg("Failed to write stream ack")
+			}
+		}
+	}()
+
+	sem := make(chan struct{}, h.inFlightWindow)
+	var wg sync.WaitGroup
+	var seq uint64
+
+	for {
+		var raw json.RawMessage
+		if err := wsjson.Read(ctx, conn, &raw); err != nil {
+			if ctx.Err() == nil {
+				log.Info().Err(err).Str("tenant_id", tenantID).Msg("Stream connection closed")
+			}
+			break
+		}
+
+		h.received.Add(1)
+		seq++
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			h.backpressureEvents.Add(1)
+			sem <- struct{}{}
+		}
+
+		wg.Add(1)
+		go func(raw json.RawMessage, seq uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			h.processMessage(raw, tenantID, seq, acks)
+		}(raw, seq)
+	}
+
+	wg.Wait()
+	close(acks)
+	<-writerDone
+}
+
+// processMessage decodes, publishes, and acks a single streamed event. It
+// runs on its own goroutine per message so that a slow publish only holds
+// up the in-flight window, not the socket's read loop.
+func (h *StreamHandler) processMessage(raw json.RawMessage, tenantID string, seq uint64, acks chan<- streamAck) {
+	event, err := decodeStreamEvent(raw, tenantID)
+	if err != nil {
+		h.dropped.Add(1)
+		acks <- streamAck{Status: "rejected", Seq: seq}
+		return
+	}
+
+	topic := "raw.events." + tenantID
+	if err := h.publisher.Publish(topic, event); err != nil {
+		log.Error().Err(err).Str("tenant_id", tenantID).Str("topic", topic).Msg("Failed to publish streamed event")
+		h.dropped.Add(1)
+		acks <- streamAck{EventID: event.EventID, Status: "error", Seq: seq}
+		return
+	}
+
+	h.published.Add(1)
+	acks <- streamAck{EventID: event.EventID, Status: "accepted", Seq: seq}
+}
+
+func (h *StreamHandler) keepalive(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(h.pingTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, h.pingTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				log.Warn().Err(err).Msg("Stream ping failed, closing connection")
+				conn.Close(websocket.StatusPolicyViolation, "ping timeout")
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) trackConn(sc *streamConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conns[sc] = struct{}{}
+}
+
+func (h *StreamHandler) untrackConn(sc *streamConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, sc)
+}
+
+// Shutdown closes every active streaming connection, which unblocks each
+// connection's read loop, then waits (bounded by ctx) for every in-flight
+// processMessage goroutine and the ack writer to drain before returning.
+// http.Server.Shutdown does not wait on hijacked connections like these, so
+// without this, events already read off a socket but still being published
+// could be lost on process exit.
+//
+// CloseNow, not Close, is used here: Close performs a close handshake that
+// waits (up to its own internal 5s timeout) to acquire the connection's read
+// lock, which the read loop's still-blocked wsjson.Read holds until a
+// message arrives or the socket is torn down — on a quiet connection that
+// makes every graceful Close during shutdown pay up to 5s for no benefit.
+// CloseNow tears down the socket directly, which unblocks that read
+// immediately.
+func (h *StreamHandler) Shutdown(ctx context.Context) {
+	h.mu.Lock()
+	for sc := range h.conns {
+		sc.conn.CloseNow()
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.connWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn().Msg("Stream shutdown timed out waiting for in-flight publishes to drain")
+	}
+}
+
+// DebugStatus returns GET /v1/ingest/stream/debug, exposing live connection
+// and throughput counters for operators.
+func (h *StreamHandler) DebugStatus(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	active := len(h.conns)
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{
+		"active_connections":  int64(active),
+		"received":            h.received.Load(),
+		"published":           h.published.Load(),
+		"dropped":             h.dropped.Load(),
+		"backpressure_events": h.backpressureEvents.Load(),
+	})
+}
+
+func decodeStreamEvent(raw json.RawMessage, tenantID string) (*models.Event, error) {
+	var event models.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, err
+	}
+
+	event.TenantID = tenantID
+	event.EventID = uuid.New().String()
+	event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	return &event, nil
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+	"github.com/siem-platform/ingest-service/internal/publisher"
+)
+
+func newTestStreamServer(t *testing.T, inFlightWindow int) (*StreamHandler, *publisher.MockPublisher, *httptest.Server) {
+	t.Helper()
+
+	mockPub := publisher.NewMockPublisher()
+	handler := NewStreamHandler(mockPub, time.Minute, inFlightWindow)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "tenant_id", "acme-corp")
+		handler.HandleStream(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	return handler, mockPub, srv
+}
+
+func dialStream(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+url[len("http"):], nil)
+	if err != nil {
+		t.Fatalf("Failed to dial stream: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+	return conn
+}
+
+func TestHandleStreamPublishesAndAcksEvents(t *testing.T) {
+	_, mockPub, srv := newTestStreamServer(t, 8)
+	conn := dialStream(t, srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := wsjson.Write(ctx, conn, map[string]interface{}{"category": "auth"}); err != nil {
+			t.Fatalf("Failed to write event: %v", err)
+		}
+	}
+
+	seen := make(map[uint64]bool)
+	for i := 0; i < 3; i++ {
+		var ack streamAck
+		if err := wsjson.Read(ctx, conn, &ack); err != nil {
+			t.Fatalf("Failed to read ack: %v", err)
+		}
+		if ack.Status != "accepted" {
+			t.Errorf("Expected ack status accepted, got %+v", ack)
+		}
+		seen[ack.Seq] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 distinct sequence numbers, got %d", len(seen))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(mockPub.GetPublished("raw.events.acme-corp")) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := len(mockPub.GetPublished("raw.events.acme-corp")); got != 3 {
+		t.Errorf("Expected 3 published events, got %d", got)
+	}
+}
+
+// TestHandleStreamSurvivesServerWriteTimeout proves acks keep flowing on a
+// connection long after a short http.Server.WriteTimeout would otherwise
+// have poisoned it. websocket.Accept hijacks the connection before
+// net/http's normal deadline-clearing path runs, so without HandleStream
+// explicitly clearing the write deadline after the handshake, every ack
+// write here would start failing with i/o timeout once WriteTimeout elapsed.
+func TestHandleStreamSurvivesServerWriteTimeout(t *testing.T) {
+	mockPub := publisher.NewMockPublisher()
+	handler := NewStreamHandler(mockPub, time.Minute, 8)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "tenant_id", "acme-corp")
+		handler.HandleStream(w, r.WithContext(ctx))
+	}))
+	srv.Config.WriteTimeout = 200 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	conn := dialStream(t, srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Wait out several multiples of WriteTimeout before writing anything, so
+	// a stuck OS-level write deadline from the hijack would have already
+	// tripped.
+	time.Sleep(5 * srv.Config.WriteTimeout)
+
+	if err := wsjson.Write(ctx, conn, map[string]interface{}{"category": "auth"}); err != nil {
+		t.Fatalf("Failed to write event: %v", err)
+	}
+
+	var ack streamAck
+	if err := wsjson.Read(ctx, conn, &ack); err != nil {
+		t.Fatalf("Failed to read ack after server WriteTimeout elapsed: %v", err)
+	}
+	if ack.Status != "accepted" {
+		t.Errorf("Expected ack status accepted, got %+v", ack)
+	}
+}
+
+func TestHandleStreamProcessesEventsConcurrently(t *testing.T) {
+	// A blocking publisher holds every in-flight publish open until the
+	// test releases it, proving HandleStream runs publishes on their own
+	// goroutines rather than serially in the read loop: if it were serial,
+	// only the first publish would ever start and the wait for the others
+	// would time out.
+	release := make(chan struct{})
+	started := make(chan struct{}, 8)
+	blockingPub := &blockingPublisher{started: started, release: release}
+
+	handler := NewStreamHandler(blockingPub, time.Minute, 8)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "tenant_id", "acme-corp")
+		handler.HandleStream(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	conn := dialStream(t, srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const inFlight = 4
+	for i := 0; i < inFlight; i++ {
+		if err := wsjson.Write(ctx, conn, map[string]interface{}{"category": "auth"}); err != nil {
+			t.Fatalf("Failed to write event: %v", err)
+		}
+	}
+
+	for i := 0; i < inFlight; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected %d concurrent in-flight publishes, only saw %d", inFlight, i)
+		}
+	}
+
+	close(release)
+}
+
+// TestStreamHandlerShutdownDrainsInFlightPublishes confirms Shutdown blocks
+// until an in-flight processMessage goroutine finishes publishing, rather
+// than returning as soon as the connection is closed: http.Server.Shutdown
+// never waits on hijacked connections like these, so if StreamHandler.
+// Shutdown didn't wait either, nothing in the shutdown path would block
+// until in-flight publishes complete.
+func TestStreamHandlerShutdownDrainsInFlightPublishes(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	blockingPub := &blockingPublisher{started: started, release: release}
+
+	handler := NewStreamHandler(blockingPub, time.Minute, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "tenant_id", "acme-corp")
+		handler.HandleStream(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	conn := dialStream(t, srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := wsjson.Write(ctx, conn, map[string]interface{}{"category": "auth"}); err != nil {
+		t.Fatalf("Failed to write event: %v", err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the publish to start before Shutdown is called")
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		handler.Shutdown(context.Background())
+		close(shutdownDone)
+	}()
+
+	select {
+	case <-shutdownDone:
+		t.Fatal("Expected Shutdown to block until the in-flight publish finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-shutdownDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Shutdown to return once the in-flight publish drained")
+	}
+
+	if got := handler.published.Load(); got != 1 {
+		t.Errorf("Expected the in-flight event to be published before Shutdown returned, got published=%d", got)
+	}
+}
+
+// blockingPublisher holds every Publish call open until release is closed,
+// after signaling on started, so a test can observe how many publishes are
+// running concurrently before letting them complete.
+type blockingPublisher struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingPublisher) Publish(topic string, event *models.Event) error {
+	b.started <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func (b *blockingPublisher) Subscribe(topic string, handler publisher.Handler) error {
+	return nil
+}
+
+func (b *blockingPublisher) Close() {}
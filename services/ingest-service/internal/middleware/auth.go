@@ -2,15 +2,108 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
+	"github.com/siem-platform/ingest-service/internal/config"
 )
 
+// allowedAlgs restricts accepted signing algorithms to prevent alg-confusion
+// attacks (e.g. a server expecting RS256 accepting an attacker-supplied HS256
+// token signed with the public key as the HMAC secret).
+var allowedAlgs = map[string]bool{
+	"RS256": true,
+	"ES256": true,
+	"EdDSA": true,
+}
+
+// TenantIssuer pins the expected issuer and audience for a single tenant, so
+// one tenant's IdP cannot mint tokens accepted for another tenant.
+type TenantIssuer struct {
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+}
+
+// JWTValidator validates JWTs against a static key, a JWKS endpoint, or an
+// OIDC-discovered JWKS endpoint, with per-tenant issuer/audience overrides.
+type JWTValidator struct {
+	keySource     KeySource
+	issuer        string
+	audience      string
+	clockSkew     time.Duration
+	tenantIssuers map[string]TenantIssuer
+}
+
+// NewJWTValidator builds a JWTValidator from configuration. JWTPublicKeyFile,
+// JWTJWKSURL, and JWTIssuer (via OIDC discovery) are tried in that order; if
+// none are set, the returned validator has no key source and JWT validation
+// is disabled (matching the prior dev-mode behavior).
+func NewJWTValidator(cfg *config.Config) (*JWTValidator, error) {
+	v := &JWTValidator{
+		issuer:    cfg.JWTIssuer,
+		audience:  cfg.JWTAudience,
+		clockSkew: cfg.JWTClockSkew,
+	}
+
+	switch {
+	case cfg.JWTPublicKeyFile != "":
+		ks, err := NewStaticKeySource(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: loading public key file: %w", err)
+		}
+		v.keySource = ks
+
+	case cfg.JWTJWKSURL != "":
+		v.keySource = NewJWKSKeySource(cfg.JWTJWKSURL)
+
+	case cfg.JWTIssuer != "":
+		ks, err := NewOIDCKeySource(cfg.JWTIssuer)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: OIDC discovery for issuer %q: %w", cfg.JWTIssuer, err)
+		}
+		v.keySource = ks
+	}
+
+	if cfg.JWTTenantIssuers != "" {
+		var tenantIssuers map[string]TenantIssuer
+		if err := json.Unmarshal([]byte(cfg.JWTTenantIssuers), &tenantIssuers); err != nil {
+			return nil, fmt.Errorf("jwt: invalid JWT_TENANT_ISSUERS: %w", err)
+		}
+		for tenantID, ti := range tenantIssuers {
+			if ti.Issuer == "" || ti.Audience == "" {
+				return nil, fmt.Errorf("jwt: JWT_TENANT_ISSUERS entry for tenant %q is missing issuer or audience", tenantID)
+			}
+		}
+		v.tenantIssuers = tenantIssuers
+	}
+
+	// iss/aud validation is mandatory whenever a key source is configured:
+	// fail closed at construction time rather than silently accepting
+	// tokens from any issuer/audience for tenants with no resolvable
+	// override.
+	if v.keySource != nil && (v.issuer == "" || v.audience == "") && len(v.tenantIssuers) == 0 {
+		return nil, fmt.Errorf("jwt: JWT_ISSUER and JWT_AUDIENCE (or JWT_TENANT_ISSUERS) must be set when a JWT key source is configured")
+	}
+
+	return v, nil
+}
+
+// issuerAudienceFor returns the issuer/audience a tenant's tokens must match,
+// falling back to the service-wide default when the tenant has no override.
+func (v *JWTValidator) issuerAudienceFor(tenantID string) (issuer, audience string) {
+	if ti, ok := v.tenantIssuers[tenantID]; ok {
+		return ti.Issuer, ti.Audience
+	}
+	return v.issuer, v.audience
+}
+
 // JWTAuth validates JWT tokens and tenant claims
-func JWTAuth(publicKey string) func(http.Handler) http.Handler {
+func JWTAuth(validator *JWTValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract Authorization header
@@ -30,23 +123,44 @@ func JWTAuth(publicKey string) func(http.Handler) http.Handler {
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-			// For MVP development: accept any token if no public key configured
-			if publicKey == "" {
-				log.Warn().Msg("JWT validation disabled (no public key configured)")
+			// For MVP development: accept any token if no key source configured
+			if validator.keySource == nil {
+				log.Warn().Msg("JWT validation disabled (no key source configured)")
 				// In dev mode, extract tenant_id from context set by TenantValidator
 				next.ServeHTTP(w, r)
 				return
 			}
 
+			headerTenantID, _ := r.Context().Value("tenant_id").(string)
+			issuer, audience := validator.issuerAudienceFor(headerTenantID)
+
+			// iss/aud validation is mandatory: a tenant with no resolvable
+			// issuer/audience (e.g. not present in JWT_TENANT_ISSUERS and no
+			// service-wide default configured) is rejected outright rather
+			// than validated with the check silently skipped.
+			if issuer == "" || audience == "" {
+				log.Warn().Str("tenant_id", headerTenantID).Msg("No issuer/audience configured for tenant")
+				http.Error(w, "Tenant not configured for JWT validation", http.StatusUnauthorized)
+				return
+			}
+
+			opts := []jwt.ParserOption{
+				jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+				jwt.WithLeeway(validator.clockSkew),
+				jwt.WithExpirationRequired(),
+				jwt.WithIssuer(issuer),
+				jwt.WithAudience(audience),
+			}
+
 			// Parse and validate JWT
 			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				// Validate signing method
-				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-					return nil, jwt.ErrSignatureInvalid
+				if !allowedAlgs[token.Method.Alg()] {
+					return nil, fmt.Errorf("jwt: algorithm %q not allowed", token.Method.Alg())
 				}
-				// TODO: Load actual public key from file/env
-				return []byte(publicKey), nil
-			})
+
+				kid, _ := token.Header["kid"].(string)
+				return validator.keySource.Key(kid)
+			}, opts...)
 
 			if err != nil {
 				log.Warn().Err(err).Msg("Invalid JWT token")
@@ -68,6 +182,17 @@ func JWTAuth(publicKey string) func(http.Handler) http.Handler {
 				return
 			}
 
+			if _, hasNbf := claims["nbf"]; !hasNbf {
+				log.Warn().Msg("Missing nbf claim")
+				http.Error(w, "Missing nbf claim", http.StatusUnauthorized)
+				return
+			}
+			if _, hasIat := claims["iat"]; !hasIat {
+				log.Warn().Msg("Missing iat claim")
+				http.Error(w, "Missing iat claim", http.StatusUnauthorized)
+				return
+			}
+
 			// Validate tenant_id in JWT matches X-Tenant-ID header
 			jwtTenantID, ok := claims["tenant_id"].(string)
 			if !ok {
@@ -76,7 +201,6 @@ func JWTAuth(publicKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			headerTenantID := r.Context().Value("tenant_id").(string)
 			if jwtTenantID != headerTenantID {
 				log.Warn().
 					Str("jwt_tenant_id", jwtTenantID).
@@ -40,6 +40,16 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap exposes the underlying ResponseWriter so http.NewResponseController
+// (and callers like websocket.Accept that type-assert for http.Hijacker or
+// http.Flusher) can see through the wrapper, per the Go 1.20+ convention.
+// Without this, wrapping an embedded ResponseWriter *interface* does not
+// promote Hijack/Flush, since those aren't part of the ResponseWriter
+// interface itself.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Chain applies middleware in order
 func Chain(handler http.Handler, middleware ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middleware) - 1; i >= 0; i-- {
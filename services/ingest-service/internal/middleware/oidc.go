@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type oidcDiscoveryDocument struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// DiscoverJWKSURL fetches issuer's /.well-known/openid-configuration and
+// returns the jwks_uri it advertises.
+func DiscoverJWKSURL(issuer string) (string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var doc oidcDiscoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", err
+	}
+
+	return doc.JWKSURI, nil
+}
+
+// NewOIDCKeySource discovers issuer's JWKS endpoint and returns a key source
+// backed by it.
+func NewOIDCKeySource(issuer string) (*JWKSKeySource, error) {
+	jwksURL, err := DiscoverJWKSURL(issuer)
+	if err != nil {
+		return nil, err
+	}
+	return NewJWKSKeySource(jwksURL), nil
+}
@@ -0,0 +1,22 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewFromConfig selects and constructs a PubSub backend by name.
+// broker is one of "nats", "kafka", or "redis".
+func NewFromConfig(broker, natsURL, kafkaBrokers, redisURL string) (PubSub, error) {
+	switch broker {
+	case "", "nats":
+		return NewNATSPublisher(natsURL)
+	case "kafka":
+		brokers := strings.Split(kafkaBrokers, ",")
+		return NewKafkaPublisher(brokers)
+	case "redis":
+		return NewRedisStreamsPublisher(redisURL)
+	default:
+		return nil, fmt.Errorf("unknown broker %q: must be nats, kafka, or redis", broker)
+	}
+}
@@ -0,0 +1,159 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeKafkaBroker is an in-process stand-in for a Kafka cluster, used so the
+// shared PubSub contract suite (TestPubSubContract) can exercise
+// KafkaPublisher's Subscribe/Close draining semantics without a live
+// broker. Each topic gets its own order-preserving queue (a channel
+// buffered to 1024 messages; writes block once it is full), and
+// FetchMessage blocks on the caller's context exactly like a real
+// *kafka.Reader blocks on the network, so Close's context-cancellation
+// drain path is exercised the same way it would be against a real cluster.
+type fakeKafkaBroker struct {
+	mu     sync.Mutex
+	queues map[string]chan kafka.Message
+}
+
+func newFakeKafkaBroker() *fakeKafkaBroker {
+	return &fakeKafkaBroker{queues: make(map[string]chan kafka.Message)}
+}
+
+func (b *fakeKafkaBroker) queue(topic string) chan kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[topic]
+	if !ok {
+		q = make(chan kafka.Message, 1024)
+		b.queues[topic] = q
+	}
+	return q
+}
+
+func (b *fakeKafkaBroker) writer(topic string) kafkaWriter {
+	return &fakeKafkaWriter{queue: b.queue(topic)}
+}
+
+func (b *fakeKafkaBroker) reader(topic string) kafkaReader {
+	return &fakeKafkaReader{queue: b.queue(topic)}
+}
+
+type fakeKafkaWriter struct {
+	queue chan kafka.Message
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		select {
+		case w.queue <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error { return nil }
+
+type fakeKafkaReader struct {
+	queue chan kafka.Message
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.queue:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeKafkaReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return nil
+}
+
+func (r *fakeKafkaReader) Close() error { return nil }
+
+// fakeRedisBroker is an in-process stand-in for Redis Streams, used so the
+// shared PubSub contract suite can exercise RedisStreamsPublisher's
+// Subscribe/Close draining semantics without a live Redis instance. Like
+// fakeKafkaBroker, each stream gets its own order-preserving queue and
+// ReadGroup blocks on the caller's context the way a real XREADGROUP with
+// Block:0 blocks on the network.
+type fakeRedisBroker struct {
+	mu     sync.Mutex
+	queues map[string]chan streamMessage
+	nextID map[string]int64
+}
+
+func newFakeRedisBroker() *fakeRedisBroker {
+	return &fakeRedisBroker{
+		queues: make(map[string]chan streamMessage),
+		nextID: make(map[string]int64),
+	}
+}
+
+func (b *fakeRedisBroker) queue(stream string) chan streamMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[stream]
+	if !ok {
+		q = make(chan streamMessage, 1024)
+		b.queues[stream] = q
+	}
+	return q
+}
+
+func (b *fakeRedisBroker) nextMessageID(stream string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID[stream]
+	b.nextID[stream] = id + 1
+	return fmt.Sprintf("%d-0", id)
+}
+
+func (b *fakeRedisBroker) client() redisStreamClient {
+	return &fakeRedisStreamClient{broker: b}
+}
+
+type fakeRedisStreamClient struct {
+	broker *fakeRedisBroker
+}
+
+func (c *fakeRedisStreamClient) XAdd(ctx context.Context, stream, tenantID string, payload []byte) error {
+	msg := streamMessage{ID: c.broker.nextMessageID(stream), TenantID: tenantID, Payload: payload}
+	select {
+	case c.broker.queue(stream) <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *fakeRedisStreamClient) EnsureGroup(ctx context.Context, stream, group string) error {
+	return nil
+}
+
+func (c *fakeRedisStreamClient) ReadGroup(ctx context.Context, stream, group, consumer string, count int64) ([]streamMessage, error) {
+	select {
+	case m := <-c.broker.queue(stream):
+		return []streamMessage{m}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeRedisStreamClient) Ack(ctx context.Context, stream, group, id string) error {
+	return nil
+}
+
+func (c *fakeRedisStreamClient) Close() error { return nil }
@@ -0,0 +1,191 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// kafkaWriter is the subset of *kafka.Writer that KafkaPublisher depends on,
+// extracted so the contract test suite can inject an in-process fake broker
+// instead of requiring a live Kafka cluster.
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// kafkaReader is the subset of *kafka.Reader that KafkaPublisher.Subscribe
+// depends on. See kafkaWriter.
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaPublisher publishes events to Apache Kafka. Each topic maps to a
+// lazily-created writer so tenant partition keys stay sticky per topic.
+type KafkaPublisher struct {
+	brokers   []string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	newWriter func(topic string) kafkaWriter
+	newReader func(topic string) kafkaReader
+
+	mu      sync.Mutex
+	writers map[string]kafkaWriter
+	readers []kafkaReader
+}
+
+// NewKafkaPublisher creates a new Kafka publisher against the given broker list.
+func NewKafkaPublisher(brokers []string) (*KafkaPublisher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &KafkaPublisher{
+		brokers: brokers,
+		ctx:     ctx,
+		cancel:  cancel,
+		writers: make(map[string]kafkaWriter),
+	}
+	p.newWriter = func(topic string) kafkaWriter {
+		return &kafka.Writer{
+			Addr:     kafka.TCP(p.brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		}
+	}
+	p.newReader = func(topic string) kafkaReader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers: p.brokers,
+			Topic:   topic,
+			GroupID: "ingest-service",
+		})
+	}
+	return p, nil
+}
+
+// newKafkaPublisherWithFactories builds a KafkaPublisher against injected
+// reader/writer factories instead of a live broker, so the shared PubSub
+// contract suite can exercise Kafka's Subscribe/Close draining semantics
+// without live infrastructure.
+func newKafkaPublisherWithFactories(newWriter func(topic string) kafkaWriter, newReader func(topic string) kafkaReader) *KafkaPublisher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &KafkaPublisher{
+		ctx:       ctx,
+		cancel:    cancel,
+		writers:   make(map[string]kafkaWriter),
+		newWriter: newWriter,
+		newReader: newReader,
+	}
+}
+
+// Publish publishes an event to a Kafka topic, keyed by tenant so all of a
+// tenant's events land on the same partition and preserve ordering.
+func (p *KafkaPublisher) Publish(topic string, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w := p.writerFor(topic)
+	err = w.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(event.TenantID),
+		Value: data,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("topic", topic).
+		Str("event_id", event.EventID).
+		Int("size_bytes", len(data)).
+		Msg("Event published to Kafka")
+
+	return nil
+}
+
+// Subscribe registers handler for messages on a Kafka topic using a
+// single-partition-aware consumer group per topic. The reader goroutine is
+// tracked so Close can cancel it and wait for it to exit before returning.
+func (p *KafkaPublisher) Subscribe(topic string, handler Handler) error {
+	reader := p.newReader(topic)
+
+	p.mu.Lock()
+	p.readers = append(p.readers, reader)
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		for {
+			msg, err := reader.FetchMessage(p.ctx)
+			if err != nil {
+				if p.ctx.Err() != nil {
+					return
+				}
+				log.Error().Err(err).Str("topic", topic).Msg("Kafka reader stopped")
+				return
+			}
+
+			var event models.Event
+			if err := json.Unmarshal(msg.Value, &event); err != nil {
+				log.Error().Err(err).Str("topic", topic).Msg("Failed to decode Kafka message")
+				continue
+			}
+
+			if err := handler(&event); err != nil {
+				log.Error().Err(err).Str("topic", topic).Str("event_id", event.EventID).Msg("Handler failed, message will be redelivered")
+				continue
+			}
+
+			if err := reader.CommitMessages(context.Background(), msg); err != nil {
+				log.Error().Err(err).Str("topic", topic).Msg("Failed to commit Kafka offset")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close stops every Subscribe reader and waits for its goroutine to exit,
+// then flushes and closes all writers opened by this publisher.
+func (p *KafkaPublisher) Close() {
+	p.cancel()
+
+	p.mu.Lock()
+	readers := p.readers
+	writers := p.writers
+	p.mu.Unlock()
+
+	for _, r := range readers {
+		if err := r.Close(); err != nil {
+			log.Warn().Err(err).Msg("Error closing Kafka reader")
+		}
+	}
+	p.wg.Wait()
+
+	for topic, w := range writers {
+		if err := w.Close(); err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("Error closing Kafka writer")
+		}
+	}
+	log.Info().Msg("Kafka connections closed")
+}
+
+func (p *KafkaPublisher) writerFor(topic string) kafkaWriter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if w, ok := p.writers[topic]; ok {
+		return w
+	}
+
+	w := p.newWriter(topic)
+	p.writers[topic] = w
+	return w
+}
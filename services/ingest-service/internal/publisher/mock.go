@@ -1,13 +1,17 @@
 package publisher
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/siem-platform/ingest-service/internal/models"
 )
 
-// MockPublisher is a mock implementation for testing
+// MockPublisher is a mock implementation for testing. Publish is safe for
+// concurrent use since both the batch and stream handlers publish from a
+// pool of goroutines in tests.
 type MockPublisher struct {
+	mu        sync.Mutex
 	published map[string][]*models.Event
 }
 
@@ -18,16 +22,35 @@ func NewMockPublisher() *MockPublisher {
 }
 
 func (m *MockPublisher) Publish(topic string, event *models.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.published[topic] = append(m.published[topic], event)
 	return nil
 }
 
+// Subscribe immediately replays any already-published messages on topic to
+// handler, then returns. It does not watch for future publishes.
+func (m *MockPublisher) Subscribe(topic string, handler Handler) error {
+	m.mu.Lock()
+	events := append([]*models.Event(nil), m.published[topic]...)
+	m.mu.Unlock()
+
+	for _, event := range events {
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockPublisher) Close() {
 	// No-op for mock
 }
 
 func (m *MockPublisher) GetPublished(topic string) []*models.Event {
-	return m.published[topic]
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*models.Event(nil), m.published[topic]...)
 }
 
 func TestMockPublisher(t *testing.T) {
@@ -2,22 +2,18 @@ package publisher
 
 import (
 	"encoding/json"
+	"sync"
 
 	"github.com/nats-io/nats.go"
 	"github.com/rs/zerolog/log"
 	"github.com/siem-platform/ingest-service/internal/models"
 )
 
-// Publisher defines the interface for event publishing
-type Publisher interface {
-	Publish(topic string, event *models.Event) error
-	Close()
-}
-
 // NATSPublisher publishes events to NATS JetStream
 type NATSPublisher struct {
 	conn *nats.Conn
 	js   nats.JetStreamContext
+	wg   sync.WaitGroup
 }
 
 // NewNATSPublisher creates a new NATS publisher
@@ -50,7 +46,9 @@ func NewNATSPublisher(url string) (*NATSPublisher, error) {
 	}, nil
 }
 
-// Publish publishes an event to a NATS topic
+// Publish publishes an event to a NATS topic. The JetStream Nats-Msg-Id
+// header is set to the event ID so JetStream's server-side dedup window acts
+// as a second line of defense behind internal/dedup.
 func (p *NATSPublisher) Publish(topic string, event *models.Event) error {
 	// Serialize event to JSON
 	data, err := json.Marshal(event)
@@ -59,7 +57,11 @@ func (p *NATSPublisher) Publish(topic string, event *models.Event) error {
 	}
 
 	// Publish to JetStream
-	_, err = p.js.Publish(topic, data)
+	_, err = p.js.PublishMsg(&nats.Msg{
+		Subject: topic,
+		Data:    data,
+		Header:  nats.Header{"Nats-Msg-Id": []string{event.EventID}},
+	})
 	if err != nil {
 		return err
 	}
@@ -73,8 +75,43 @@ func (p *NATSPublisher) Publish(topic string, event *models.Event) error {
 	return nil
 }
 
-// Close closes the NATS connection
+// Subscribe registers handler for messages on a NATS JetStream durable
+// consumer. NATS dispatches each message on its own async callback goroutine
+// rather than a loop this package controls, so in-flight handler execution
+// is tracked with a WaitGroup around the callback body instead of around a
+// reader goroutine, letting Close wait for a callback already running to
+// finish instead of abandoning it mid-handler.
+func (p *NATSPublisher) Subscribe(topic string, handler Handler) error {
+	_, err := p.js.Subscribe(topic, func(msg *nats.Msg) {
+		p.wg.Add(1)
+		defer p.wg.Done()
+
+		var event models.Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Error().Err(err).Str("topic", topic).Msg("Failed to decode NATS message")
+			return
+		}
+
+		if err := handler(&event); err != nil {
+			log.Error().Err(err).Str("topic", topic).Str("event_id", event.EventID).Msg("Handler failed, message will be redelivered")
+			return
+		}
+
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Str("topic", topic).Msg("Failed to ack NATS message")
+		}
+	}, nats.ManualAck())
+
+	return err
+}
+
+// Close drains any in-flight Subscribe handler before closing the NATS
+// connection. conn.Close() only signals the async dispatch loop to stop; it
+// does not wait for a callback that's already running, so without this wait
+// a handler could be cut off mid-publish when the process shuts down.
 func (p *NATSPublisher) Close() {
+	p.wg.Wait()
+
 	if p.conn != nil {
 		p.conn.Close()
 		log.Info().Msg("NATS connection closed")
@@ -0,0 +1,24 @@
+package publisher
+
+import "github.com/siem-platform/ingest-service/internal/models"
+
+// Handler processes a single event delivered by a PubSub subscription.
+// Returning an error leaves the message unacknowledged so the backend can redeliver it.
+type Handler func(event *models.Event) error
+
+// PubSub is the broker-agnostic messaging abstraction used throughout the
+// ingest pipeline. Publish must preserve ordering per tenant partition key
+// (the topic), and Subscribe must deliver at-least-once.
+type PubSub interface {
+	// Publish writes event to topic, blocking until the backend has accepted it.
+	Publish(topic string, event *models.Event) error
+	// Subscribe registers handler for messages on topic until ctx-equivalent
+	// shutdown via Close. Subscribe may be called multiple times for different topics.
+	Subscribe(topic string, handler Handler) error
+	// Close drains in-flight work and releases the underlying connection.
+	Close()
+}
+
+// Publisher is kept as an alias of PubSub for source compatibility with
+// existing callers that only ever used the publish half of the interface.
+type Publisher = PubSub
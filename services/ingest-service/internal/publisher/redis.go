@@ -0,0 +1,213 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// streamMessage is a decoded Redis Streams entry, abstracted away from the
+// go-redis client's own XMessage type so redisStreamClient implementations
+// don't need to round-trip through a live client's result types.
+type streamMessage struct {
+	ID       string
+	TenantID string
+	Payload  []byte
+}
+
+// redisStreamClient is the subset of Redis Streams functionality
+// RedisStreamsPublisher depends on, extracted so the contract test suite can
+// inject an in-process fake broker instead of requiring a live Redis
+// instance.
+type redisStreamClient interface {
+	XAdd(ctx context.Context, stream, tenantID string, payload []byte) error
+	EnsureGroup(ctx context.Context, stream, group string) error
+	ReadGroup(ctx context.Context, stream, group, consumer string, count int64) ([]streamMessage, error)
+	Ack(ctx context.Context, stream, group, id string) error
+	Close() error
+}
+
+// realRedisStreamClient adapts a live *redis.Client to redisStreamClient.
+type realRedisStreamClient struct {
+	client *redis.Client
+}
+
+func (c *realRedisStreamClient) XAdd(ctx context.Context, stream, tenantID string, payload []byte) error {
+	return c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]interface{}{
+			"tenant_id": tenantID,
+			"payload":   payload,
+		},
+	}).Err()
+}
+
+func (c *realRedisStreamClient) EnsureGroup(ctx context.Context, stream, group string) error {
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && err.Error() == "BUSYGROUP Consumer Group name already exists" {
+		return nil
+	}
+	return err
+}
+
+func (c *realRedisStreamClient) ReadGroup(ctx context.Context, stream, group, consumer string, count int64) ([]streamMessage, error) {
+	result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    0,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []streamMessage
+	for _, s := range result {
+		for _, msg := range s.Messages {
+			raw, ok := msg.Values["payload"].(string)
+			if !ok {
+				log.Error().Str("stream", stream).Str("id", msg.ID).Msg("Redis Streams message missing payload")
+				continue
+			}
+			tenantID, _ := msg.Values["tenant_id"].(string)
+			messages = append(messages, streamMessage{ID: msg.ID, TenantID: tenantID, Payload: []byte(raw)})
+		}
+	}
+	return messages, nil
+}
+
+func (c *realRedisStreamClient) Ack(ctx context.Context, stream, group, id string) error {
+	return c.client.XAck(ctx, stream, group, id).Err()
+}
+
+func (c *realRedisStreamClient) Close() error {
+	return c.client.Close()
+}
+
+// RedisStreamsPublisher publishes events to Redis Streams, using XADD/XREADGROUP
+// so that consumer groups get the same at-least-once delivery guarantee as JetStream.
+type RedisStreamsPublisher struct {
+	client redisStreamClient
+	group  string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRedisStreamsPublisher creates a new Redis Streams publisher against url.
+func NewRedisStreamsPublisher(url string) (*RedisStreamsPublisher, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisStreamsPublisher{
+		client: &realRedisStreamClient{client: client},
+		group:  "ingest-service",
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// newRedisStreamsPublisherWithClient builds a RedisStreamsPublisher against
+// an injected redisStreamClient instead of a live Redis instance, so the
+// shared PubSub contract suite can exercise Subscribe/Close draining
+// semantics without live infrastructure.
+func newRedisStreamsPublisherWithClient(client redisStreamClient) *RedisStreamsPublisher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisStreamsPublisher{
+		client: client,
+		group:  "ingest-service",
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Publish appends an event to a Redis stream. The tenant ID is carried as a
+// field so ordering within a tenant can be reconstructed by consumers.
+func (p *RedisStreamsPublisher) Publish(topic string, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.XAdd(context.Background(), topic, event.TenantID, data); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("topic", topic).
+		Str("event_id", event.EventID).
+		Int("size_bytes", len(data)).
+		Msg("Event published to Redis Streams")
+
+	return nil
+}
+
+// Subscribe registers handler for messages on a Redis stream via a consumer
+// group. The reader goroutine blocks on p.ctx rather than context.Background,
+// so Close can unblock and stop it instead of leaving it parked in
+// XReadGroup forever.
+func (p *RedisStreamsPublisher) Subscribe(topic string, handler Handler) error {
+	if err := p.client.EnsureGroup(p.ctx, topic, p.group); err != nil {
+		return err
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		for {
+			messages, err := p.client.ReadGroup(p.ctx, topic, p.group, "ingest-service-1", 10)
+			if err != nil {
+				if p.ctx.Err() != nil {
+					return
+				}
+				log.Error().Err(err).Str("topic", topic).Msg("Redis Streams reader stopped")
+				return
+			}
+
+			for _, msg := range messages {
+				var event models.Event
+				if err := json.Unmarshal(msg.Payload, &event); err != nil {
+					log.Error().Err(err).Str("topic", topic).Msg("Failed to decode Redis Streams message")
+					continue
+				}
+
+				if err := handler(&event); err != nil {
+					log.Error().Err(err).Str("topic", topic).Str("event_id", event.EventID).Msg("Handler failed, message will be redelivered")
+					continue
+				}
+
+				if err := p.client.Ack(p.ctx, topic, p.group, msg.ID); err != nil {
+					log.Error().Err(err).Str("topic", topic).Msg("Failed to ack Redis Streams message")
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close cancels every Subscribe reader, waits for its goroutine to exit,
+// then closes the Redis client connection.
+func (p *RedisStreamsPublisher) Close() {
+	p.cancel()
+	p.wg.Wait()
+
+	if err := p.client.Close(); err != nil {
+		log.Warn().Err(err).Msg("Error closing Redis connection")
+	}
+	log.Info().Msg("Redis Streams connection closed")
+}
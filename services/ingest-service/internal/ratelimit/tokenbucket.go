@@ -0,0 +1,61 @@
+// Package ratelimit provides simple per-key rate limiting primitives.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a per-key token bucket limiter, used to cap ingest volume
+// per tenant without one tenant's burst starving another's.
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64 // tokens refilled per second
+	buckets  map[string]*bucketState
+}
+
+type bucketState struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// New creates a token bucket limiter with the given burst capacity and
+// steady-state refill rate (tokens per second).
+func New(capacity, refillPerSecond float64) *TokenBucket {
+	return &TokenBucket{
+		capacity: capacity,
+		rate:     refillPerSecond,
+		buckets:  make(map[string]*bucketState),
+	}
+}
+
+// Allow reports whether n tokens are available for key, consuming them if so.
+func (t *TokenBucket) Allow(key string, n float64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucketState{tokens: t.capacity, lastSeen: now}
+		t.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = minFloat(t.capacity, b.tokens+elapsed*t.rate)
+	b.lastSeen = now
+
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
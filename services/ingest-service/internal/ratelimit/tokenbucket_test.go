@@ -0,0 +1,31 @@
+package ratelimit
+
+import "testing"
+
+func TestTokenBucketAllowsWithinCapacity(t *testing.T) {
+	tb := New(5, 1)
+
+	for i := 0; i < 5; i++ {
+		if !tb.Allow("tenant-a", 1) {
+			t.Fatalf("Expected request %d to be allowed", i)
+		}
+	}
+
+	if tb.Allow("tenant-a", 1) {
+		t.Error("Expected request beyond capacity to be denied")
+	}
+}
+
+func TestTokenBucketIsolatesKeys(t *testing.T) {
+	tb := New(1, 0)
+
+	if !tb.Allow("tenant-a", 1) {
+		t.Fatal("Expected first request for tenant-a to be allowed")
+	}
+	if !tb.Allow("tenant-b", 1) {
+		t.Error("Expected tenant-b to have its own bucket")
+	}
+	if tb.Allow("tenant-a", 1) {
+		t.Error("Expected tenant-a to be exhausted")
+	}
+}
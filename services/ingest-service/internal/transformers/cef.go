@@ -0,0 +1,87 @@
+package transformers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// CEFTransformer parses ArcSight Common Event Format messages:
+//
+//	CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+//
+// Mapping table (CEF field -> canonical field):
+//
+//	Device Vendor + Device Product -> source.system ("vendor product")
+//	Device Version                 -> source.integration
+//	Signature ID                   -> category
+//	Name                           -> action
+//	Severity (0-10)                -> severity
+//	Extension key "suser"          -> actor.id
+//	Extension key "duser"          -> target.id
+//	Extension key "outcome"        -> outcome
+//	Extension key "dst"            -> target.name
+type CEFTransformer struct{}
+
+func (CEFTransformer) Transform(raw []byte, contentType string) (*models.Event, error) {
+	line := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(line, "CEF:") {
+		return nil, &FieldError{Field: "$", Message: "missing CEF: prefix"}
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(line, "CEF:"), "|", 8)
+	if len(fields) < 7 {
+		return nil, &FieldError{Field: "$", Message: "expected at least 7 pipe-delimited CEF fields"}
+	}
+
+	severity, err := strconv.Atoi(fields[6])
+	if err != nil {
+		return nil, &FieldError{Field: "Severity", Message: "not an integer"}
+	}
+
+	event := &models.Event{
+		Source: models.Source{
+			System:      fields[1] + " " + fields[2],
+			Integration: fields[3],
+		},
+		Category: fields[4],
+		Action:   fields[5],
+		Severity: severity,
+		RawRef:   rawRef(raw),
+	}
+
+	if len(fields) == 8 {
+		ext := parseCEFExtension(fields[7])
+		if v, ok := ext["suser"]; ok {
+			event.Actor = &models.Actor{Type: "user", ID: v}
+		}
+		if v, ok := ext["duser"]; ok {
+			event.Target = &models.Target{Type: "user", ID: v}
+		}
+		if v, ok := ext["dst"]; ok {
+			if event.Target == nil {
+				event.Target = &models.Target{Type: "asset", ID: v}
+			}
+			event.Target.Name = v
+		}
+		if v, ok := ext["outcome"]; ok {
+			event.Outcome = v
+		}
+	}
+
+	return event, nil
+}
+
+// parseCEFExtension splits the CEF extension field into its key=value pairs.
+// It does not attempt to unescape backslash-escaped spaces within values.
+func parseCEFExtension(extension string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Fields(extension) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package transformers
+
+import (
+	"encoding/json"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// JSONTransformer handles application/json payloads already in the canonical
+// models.Event shape. No field mapping is needed.
+type JSONTransformer struct{}
+
+func (JSONTransformer) Transform(raw []byte, contentType string) (*models.Event, error) {
+	var event models.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, &FieldError{Field: "$", Message: err.Error()}
+	}
+
+	event.RawRef = rawRef(raw)
+	return &event, nil
+}
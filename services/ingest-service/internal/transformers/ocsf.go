@@ -0,0 +1,63 @@
+package transformers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// ocsfEvent captures the subset of the OCSF event envelope this transformer maps.
+type ocsfEvent struct {
+	CategoryUID  int    `json:"category_uid"`
+	SeverityID   int    `json:"severity_id"`
+	ActivityName string `json:"activity_name"`
+	Status       string `json:"status"`
+	Actor        struct {
+		User struct {
+			UID  string `json:"uid"`
+			Name string `json:"name"`
+		} `json:"user"`
+	} `json:"actor"`
+	Device struct {
+		Hostname string `json:"hostname"`
+	} `json:"device"`
+}
+
+// OCSFTransformer parses Open Cybersecurity Schema Framework (OCSF) events.
+//
+// Mapping table (OCSF field -> canonical field):
+//
+//	category_uid   -> category
+//	severity_id    -> severity
+//	activity_name  -> action
+//	status         -> outcome
+//	actor.user.uid -> actor.id
+//	device.hostname -> source.host
+type OCSFTransformer struct{}
+
+func (OCSFTransformer) Transform(raw []byte, contentType string) (*models.Event, error) {
+	var src ocsfEvent
+	if err := json.Unmarshal(raw, &src); err != nil {
+		return nil, &FieldError{Field: "$", Message: err.Error()}
+	}
+
+	if src.CategoryUID == 0 {
+		return nil, &FieldError{Field: "category_uid", Message: "required"}
+	}
+
+	event := &models.Event{
+		Source:   models.Source{Host: src.Device.Hostname, System: "ocsf"},
+		Category: strconv.Itoa(src.CategoryUID),
+		Action:   src.ActivityName,
+		Outcome:  src.Status,
+		Severity: src.SeverityID,
+		RawRef:   rawRef(raw),
+	}
+
+	if src.Actor.User.UID != "" {
+		event.Actor = &models.Actor{Type: "user", ID: src.Actor.User.UID, Name: src.Actor.User.Name}
+	}
+
+	return event, nil
+}
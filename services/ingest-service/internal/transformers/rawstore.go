@@ -0,0 +1,44 @@
+package transformers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// rawStoreDir is the base directory raw payload bytes are written under
+// before a RawRef is handed back to the caller. It defaults to a local,
+// on-disk dev store; SetRawStoreDir points it at a shared/object-store-backed
+// mount in production, mirroring how PubSub and dedup backends are wired
+// from config at startup.
+var rawStoreDir = "data/raw"
+
+// SetRawStoreDir configures where rawRef persists raw payload bytes.
+func SetRawStoreDir(dir string) {
+	rawStoreDir = dir
+}
+
+// rawRef persists raw under the configured store and returns its
+// content-addressed reference, so the original bytes survive independently
+// of the canonical event derived from them. A write failure is logged but
+// doesn't fail the transform: the ref is still returned so the event isn't
+// dropped over a storage hiccup, though the "raw bytes" part of the
+// preservation contract will degrade for that one payload.
+func rawRef(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	ref := hex.EncodeToString(sum[:])
+
+	path := filepath.Join(rawStoreDir, ref[:2], ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Error().Err(err).Str("raw_ref", ref).Msg("Failed to create raw store directory")
+		return "raw/" + ref
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		log.Error().Err(err).Str("raw_ref", ref).Msg("Failed to persist raw payload")
+	}
+
+	return "raw/" + ref
+}
@@ -0,0 +1,25 @@
+package transformers
+
+// registry maps a Content-Type (or ?format= query value) to the Transformer
+// that handles it. Third-party transformers can call Register at startup to
+// support additional source formats without modifying this package.
+var registry = map[string]Transformer{}
+
+// Register associates contentType with t, overwriting any existing entry.
+func Register(contentType string, t Transformer) {
+	registry[contentType] = t
+}
+
+// Get looks up the transformer registered for contentType.
+func Get(contentType string) (Transformer, bool) {
+	t, ok := registry[contentType]
+	return t, ok
+}
+
+func init() {
+	Register("application/json", JSONTransformer{})
+	Register("application/cef", CEFTransformer{})
+	Register("application/syslog+rfc5424", SyslogTransformer{})
+	Register("application/ocsf+json", OCSFTransformer{})
+	Register("application/vnd.ms-winevent+xml", WinEventTransformer{})
+}
@@ -0,0 +1,49 @@
+package transformers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// syslogPattern matches an RFC 5424 header:
+//
+//	<PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+var syslogPattern = regexp.MustCompile(`^<(\d{1,3})>(\d) \S+ (\S+) (\S+) (\S+) (\S+) (?:-|\[.*\]) ?(.*)$`)
+
+// SyslogTransformer parses RFC 5424 syslog messages.
+//
+// Mapping table (RFC 5424 field -> canonical field):
+//
+//	HOSTNAME  -> source.host
+//	APP-NAME  -> source.system
+//	PRI % 8   -> severity (syslog severity, 0=emergency .. 7=debug)
+//	MSG       -> action
+type SyslogTransformer struct{}
+
+func (SyslogTransformer) Transform(raw []byte, contentType string) (*models.Event, error) {
+	matches := syslogPattern.FindStringSubmatch(strings.TrimSpace(string(raw)))
+	if matches == nil {
+		return nil, &FieldError{Field: "$", Message: "does not match RFC 5424 header"}
+	}
+
+	pri, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil, &FieldError{Field: "PRI", Message: "not an integer"}
+	}
+
+	event := &models.Event{
+		Source: models.Source{
+			Host:   matches[3],
+			System: matches[4],
+		},
+		Category: "syslog",
+		Action:   matches[7],
+		Severity: pri % 8,
+		RawRef:   rawRef(raw),
+	}
+
+	return event, nil
+}
@@ -0,0 +1,25 @@
+// Package transformers maps source-specific ingest payloads (Syslog, CEF,
+// OCSF, Windows Event XML, ...) into the canonical models.Event schema.
+package transformers
+
+import (
+	"fmt"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// Transformer converts a source-specific payload into the canonical event schema.
+type Transformer interface {
+	Transform(raw []byte, contentType string) (*models.Event, error)
+}
+
+// FieldError reports a structured failure at a specific field path within the
+// source payload, so callers can surface exactly what was malformed.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %q: %s", e.Field, e.Message)
+}
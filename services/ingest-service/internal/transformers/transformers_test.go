@@ -0,0 +1,141 @@
+package transformers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "rawstore")
+	if err != nil {
+		panic(err)
+	}
+
+	SetRawStoreDir(dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("Failed to load fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestCEFTransformer(t *testing.T) {
+	event, err := CEFTransformer{}.Transform(loadFixture(t, "cef_basic.txt"), "application/cef")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if event.Category != "100" {
+		t.Errorf("Expected category 100, got %s", event.Category)
+	}
+	if event.Severity != 5 {
+		t.Errorf("Expected severity 5, got %d", event.Severity)
+	}
+	if event.Actor == nil || event.Actor.ID != "jdoe" {
+		t.Errorf("Expected actor ID jdoe, got %+v", event.Actor)
+	}
+	if event.Outcome != "failure" {
+		t.Errorf("Expected outcome failure, got %s", event.Outcome)
+	}
+}
+
+func TestSyslogTransformer(t *testing.T) {
+	event, err := SyslogTransformer{}.Transform(loadFixture(t, "syslog_basic.txt"), "application/syslog+rfc5424")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if event.Source.Host != "mymachine.example.com" {
+		t.Errorf("Expected host mymachine.example.com, got %s", event.Source.Host)
+	}
+	if event.Source.System != "su" {
+		t.Errorf("Expected system su, got %s", event.Source.System)
+	}
+	if event.Severity != 2 {
+		t.Errorf("Expected severity 2, got %d", event.Severity)
+	}
+}
+
+func TestOCSFTransformer(t *testing.T) {
+	event, err := OCSFTransformer{}.Transform(loadFixture(t, "ocsf_basic.json"), "application/ocsf+json")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if event.Category != "3" {
+		t.Errorf("Expected category 3, got %s", event.Category)
+	}
+	if event.Actor == nil || event.Actor.ID != "123" {
+		t.Errorf("Expected actor ID 123, got %+v", event.Actor)
+	}
+	if event.Outcome != "Success" {
+		t.Errorf("Expected outcome Success, got %s", event.Outcome)
+	}
+}
+
+func TestWinEventTransformer(t *testing.T) {
+	event, err := WinEventTransformer{}.Transform(loadFixture(t, "winevent_basic.xml"), "application/vnd.ms-winevent+xml")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if event.Category != "4624" {
+		t.Errorf("Expected category 4624, got %s", event.Category)
+	}
+	if event.Source.Host != "HOST1.corp.local" {
+		t.Errorf("Expected host HOST1.corp.local, got %s", event.Source.Host)
+	}
+	if event.Actor == nil || event.Actor.Name != "jdoe" {
+		t.Errorf("Expected actor name jdoe, got %+v", event.Actor)
+	}
+}
+
+func TestRawRefPersistsOriginalBytes(t *testing.T) {
+	raw := loadFixture(t, "cef_basic.txt")
+
+	event, err := CEFTransformer{}.Transform(raw, "application/cef")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	if event.RawRef == "" {
+		t.Fatal("Expected a non-empty RawRef")
+	}
+
+	ref := strings.TrimPrefix(event.RawRef, "raw/")
+	stored, err := os.ReadFile(filepath.Join(rawStoreDir, ref[:2], ref))
+	if err != nil {
+		t.Fatalf("Expected RawRef to point at a persisted file, got: %v", err)
+	}
+	if string(stored) != string(raw) {
+		t.Errorf("Expected persisted bytes to match the original payload, got %q", stored)
+	}
+}
+
+func TestMalformedCEFRejected(t *testing.T) {
+	_, err := CEFTransformer{}.Transform([]byte("not cef"), "application/cef")
+	if err == nil {
+		t.Fatal("Expected error for malformed CEF input")
+	}
+
+	var fieldErr *FieldError
+	if !isFieldError(err, &fieldErr) {
+		t.Errorf("Expected *FieldError, got %T", err)
+	}
+}
+
+func isFieldError(err error, target **FieldError) bool {
+	fe, ok := err.(*FieldError)
+	if ok {
+		*target = fe
+	}
+	return ok
+}
@@ -0,0 +1,66 @@
+package transformers
+
+import (
+	"encoding/xml"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// winEventXML captures the subset of the Windows Event Log XML schema this
+// transformer maps (http://schemas.microsoft.com/win/2004/08/events/event).
+type winEventXML struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID  string `xml:"EventID"`
+		Level    int    `xml:"Level"`
+		Computer string `xml:"Computer"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"EventData"`
+}
+
+// WinEventTransformer parses Windows Event Log XML.
+//
+// Mapping table (Windows Event field -> canonical field):
+//
+//	System/EventID         -> category
+//	System/Provider@Name    -> source.system
+//	System/Computer         -> source.host
+//	System/Level            -> severity
+//	EventData/Data[Name=TargetUserName] -> actor.name
+type WinEventTransformer struct{}
+
+func (WinEventTransformer) Transform(raw []byte, contentType string) (*models.Event, error) {
+	var src winEventXML
+	if err := xml.Unmarshal(raw, &src); err != nil {
+		return nil, &FieldError{Field: "$", Message: err.Error()}
+	}
+
+	if src.System.EventID == "" {
+		return nil, &FieldError{Field: "System/EventID", Message: "required"}
+	}
+
+	event := &models.Event{
+		Source: models.Source{
+			System: src.System.Provider.Name,
+			Host:   src.System.Computer,
+		},
+		Category: src.System.EventID,
+		Severity: src.System.Level,
+		RawRef:   rawRef(raw),
+	}
+
+	for _, data := range src.EventData.Data {
+		if data.Name == "TargetUserName" {
+			event.Actor = &models.Actor{Type: "user", Name: data.Value}
+		}
+	}
+
+	return event, nil
+}
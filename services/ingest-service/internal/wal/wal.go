@@ -0,0 +1,187 @@
+// Package wal is a minimal append-only write-ahead log used to protect
+// accepted-but-not-yet-published batch ingest events against a crash.
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/siem-platform/ingest-service/internal/models"
+)
+
+// checkpointInterval bounds how many acknowledged entries accumulate
+// before Ack rewrites the log to drop them, so steady-state operation
+// doesn't grow the WAL unbounded over the life of the process.
+const checkpointInterval = 100
+
+// Entry pairs an event with the topic it was about to be published to.
+type Entry struct {
+	Topic string        `json:"topic"`
+	Event *models.Event `json:"event"`
+}
+
+// WAL is an append-only, newline-delimited-JSON log file. An entry stays
+// in the file until Ack is called for it, so a crash before Ack leaves it
+// in place for the next Replay.
+type WAL struct {
+	mu                  sync.Mutex
+	path                string
+	file                *os.File
+	pending             map[string]Entry
+	acksSinceCheckpoint int
+}
+
+// Open opens (creating if necessary) the WAL file at path for appending.
+func Open(path string) (*WAL, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WAL{path: path, file: f, pending: make(map[string]Entry)}, nil
+}
+
+// Append durably records entry before it is handed to the publisher.
+func (w *WAL) Append(entry Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if _, err := w.file.Write(data); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	w.pending[entry.Event.EventID] = entry
+	return nil
+}
+
+// Replay reads every entry currently in the WAL, in file order, so the
+// caller can republish anything that never made it to the broker. Entries
+// appended since the last checkpoint come back in append order; a
+// checkpoint rewrites the entries it retains in no defined order. Replayed
+// entries remain tracked as pending until the caller Acks them.
+func (w *WAL) Replay() ([]Entry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.Open(w.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		w.pending[entry.Event.EventID] = entry
+	}
+
+	return entries, scanner.Err()
+}
+
+// Ack marks eventID as durably published, dropping it from the set of
+// entries a future Checkpoint needs to retain. Once enough entries have
+// accumulated since the last checkpoint, Ack rewrites the log in place so
+// successfully-published events don't linger in the file indefinitely.
+func (w *WAL) Ack(eventID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.pending[eventID]; !ok {
+		return nil
+	}
+	delete(w.pending, eventID)
+	w.acksSinceCheckpoint++
+
+	if w.acksSinceCheckpoint < checkpointInterval {
+		return nil
+	}
+	return w.checkpointLocked()
+}
+
+// Checkpoint rewrites the log to contain only entries that have not yet
+// been acknowledged, regardless of how many acknowledgements have
+// accumulated since the last checkpoint. Callers use this to force a
+// rewrite at a known point, such as right after a startup replay.
+func (w *WAL) Checkpoint() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.checkpointLocked()
+}
+
+// checkpointLocked rewrites the log to hold only w.pending. It writes to a
+// temp file in the same directory, fsyncs it, and renames it over w.path so
+// a crash mid-checkpoint finds either the old log or the new one, never a
+// truncated one.
+func (w *WAL) checkpointLocked() error {
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), ".wal-checkpoint-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	for _, entry := range w.pending {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		data = append(data, '\n')
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		return err
+	}
+
+	// The renamed file is a new inode; w.file still appends to the old one,
+	// so it must be reopened against w.path for subsequent Append calls.
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+
+	w.acksSinceCheckpoint = 0
+	return nil
+}
+
+// Close closes the underlying WAL file.
+func (w *WAL) Close() error {
+	return w.file.Close()
+}
@@ -5,15 +5,20 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/siem-platform/ingest-service/internal/config"
+	"github.com/siem-platform/ingest-service/internal/dedup"
 	"github.com/siem-platform/ingest-service/internal/handlers"
 	"github.com/siem-platform/ingest-service/internal/middleware"
 	"github.com/siem-platform/ingest-service/internal/publisher"
+	"github.com/siem-platform/ingest-service/internal/ratelimit"
+	"github.com/siem-platform/ingest-service/internal/transformers"
+	"github.com/siem-platform/ingest-service/internal/wal"
 )
 
 func main() {
@@ -37,17 +42,47 @@ func main() {
 		Str("port", cfg.Port).
 		Msg("Starting ingest service")
 
-	// Initialize NATS publisher
-	pub, err := publisher.NewNATSPublisher(cfg.NATSURL)
+	// Initialize the configured PubSub backend
+	pub, err := publisher.NewFromConfig(cfg.Broker, cfg.NATSURL, cfg.KafkaBrokers, cfg.RedisURL)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to NATS")
+		log.Fatal().Err(err).Msg("Failed to connect to message broker")
 	}
 	defer pub.Close()
 
-	log.Info().Str("nats_url", cfg.NATSURL).Msg("Connected to NATS")
+	log.Info().Str("broker", cfg.Broker).Msg("Connected to message broker")
 
-	// Create HTTP handler
-	handler := handlers.NewIngestHandler(pub)
+	// Point ingest transformers at the raw payload store
+	if err := os.MkdirAll(cfg.RawStoreDir, 0o755); err != nil {
+		log.Fatal().Err(err).Msg("Failed to create raw store directory")
+	}
+	transformers.SetRawStoreDir(cfg.RawStoreDir)
+
+	// Initialize the batch ingest WAL
+	if err := os.MkdirAll(filepath.Dir(cfg.WALPath), 0o755); err != nil {
+		log.Fatal().Err(err).Msg("Failed to create WAL directory")
+	}
+	batchWAL, err := wal.Open(cfg.WALPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open WAL")
+	}
+	defer batchWAL.Close()
+
+	limiter := ratelimit.New(cfg.RateLimitBurst, cfg.RateLimitPerSecond)
+
+	jwtValidator, err := middleware.NewJWTValidator(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize JWT validator")
+	}
+
+	dedupStore, err := dedup.NewFromConfig(cfg.DedupBackend, cfg.DedupCapacity, cfg.RedisURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize idempotency store")
+	}
+
+	// Create HTTP handlers
+	handler := handlers.NewIngestHandler(pub, dedupStore, cfg.IdempotencyTTL)
+	streamHandler := handlers.NewStreamHandler(pub, cfg.PingTimeout, cfg.StreamInFlightWindow)
+	batchHandler := handlers.NewBatchHandler(pub, batchWAL, limiter, dedupStore, cfg.IdempotencyTTL, cfg.IngestWorkers, cfg.BatchMaxEvents, cfg.QueueHighWaterMark)
 
 	// Setup middleware chain
 	mux := http.NewServeMux()
@@ -55,18 +90,21 @@ func main() {
 	// Health endpoints (no auth required)
 	mux.HandleFunc("/health", handlers.HealthHandler)
 	mux.HandleFunc("/ready", handlers.ReadyHandler)
-	mux.HandleFunc("/metrics", handlers.MetricsHandler)
+	mux.HandleFunc("/metrics", batchHandler.Metrics)
 
 	// Ingest endpoints (with auth)
 	authMux := http.NewServeMux()
 	authMux.HandleFunc("/v1/ingest/events", handler.IngestEvents)
+	authMux.HandleFunc("/v1/ingest/stream", streamHandler.HandleStream)
+	authMux.HandleFunc("/v1/ingest/stream/debug", streamHandler.DebugStatus)
+	authMux.HandleFunc("/v1/ingest/batch", batchHandler.IngestBatch)
 
 	// Apply middleware: logging -> tenant validation -> JWT auth
 	chain := middleware.Chain(
 		authMux,
 		middleware.RequestLogger,
 		middleware.TenantValidator(cfg.JWTPublicKey),
-		middleware.JWTAuth(cfg.JWTPublicKey),
+		middleware.JWTAuth(jwtValidator),
 	)
 
 	mux.Handle("/v1/", chain)
@@ -99,9 +137,17 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// srv.Shutdown must run first: it stops the listener from accepting new
+	// connections (including new /v1/ingest/stream upgrades), so the set of
+	// hijacked stream connections is final by the time streamHandler.Shutdown
+	// walks it. Draining first would leave a window where a straggler stream
+	// connection, accepted after the drain loop already ran, is never
+	// registered and never waited on.
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	streamHandler.Shutdown(ctx)
+
 	log.Info().Msg("Server exited")
 }
@@ -0,0 +1,24 @@
+// Package messaging re-exports the ingest service's PubSub abstraction so
+// downstream consumers can depend on a stable, versioned import path instead
+// of reaching into internal/publisher.
+package messaging
+
+import (
+	"github.com/siem-platform/ingest-service/internal/models"
+	"github.com/siem-platform/ingest-service/internal/publisher"
+)
+
+// PubSub is the broker-agnostic messaging interface shared by this service
+// and downstream consumers of raw.events.<tenant> topics.
+type PubSub = publisher.PubSub
+
+// Handler processes a single delivered event.
+type Handler = publisher.Handler
+
+// Event is the canonical event schema carried over every backend.
+type Event = models.Event
+
+// New selects and constructs a PubSub backend by name ("nats", "kafka", "redis").
+func New(broker, natsURL, kafkaBrokers, redisURL string) (PubSub, error) {
+	return publisher.NewFromConfig(broker, natsURL, kafkaBrokers, redisURL)
+}